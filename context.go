@@ -0,0 +1,43 @@
+package negroni
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// timeoutHandler wraps a Handler with a per-handler context.Context
+// deadline, added via Negroni.UseWithTimeout. If the deadline has passed when
+// the wrapped handler calls next or returns, and nothing has been written
+// yet, a 504 is written; in the first case the rest of the chain is not
+// called.
+type timeoutHandler struct {
+	handler Handler
+	timeout time.Duration
+}
+
+func (t timeoutHandler) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	ctx, cancel := context.WithTimeout(r.Context(), t.timeout)
+	defer cancel()
+
+	t.handler.ServeHTTP(rw, r.WithContext(ctx), func(rw http.ResponseWriter, r *http.Request) {
+		if ctx.Err() != nil {
+			writeTimeout(rw)
+			return
+		}
+		next(rw, r)
+	})
+
+	if ctx.Err() != nil {
+		writeTimeout(rw)
+	}
+}
+
+func (t timeoutHandler) Unwrap() Handler { return t.handler }
+
+func writeTimeout(rw http.ResponseWriter) {
+	if w, ok := rw.(ResponseWriter); ok && w.Written() {
+		return
+	}
+	rw.WriteHeader(http.StatusGatewayTimeout)
+}
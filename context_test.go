@@ -0,0 +1,97 @@
+package negroni
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestUseWithTimeoutWritesGatewayTimeout(t *testing.T) {
+	n := New()
+	n.UseWithTimeout(10*time.Millisecond, HandlerFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		<-r.Context().Done()
+		next(rw, r)
+	}))
+
+	rw := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/slow", nil)
+	n.ServeHTTP(rw, r)
+
+	if rw.Code != http.StatusGatewayTimeout {
+		t.Errorf("status = %d, want %d", rw.Code, http.StatusGatewayTimeout)
+	}
+}
+
+func TestUseWithTimeoutLetsFastHandlersThrough(t *testing.T) {
+	n := New()
+	n.UseWithTimeout(time.Second, HandlerFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		rw.WriteHeader(http.StatusOK)
+	}))
+
+	rw := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/fast", nil)
+	n.ServeHTTP(rw, r)
+
+	if rw.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d", rw.Code, http.StatusOK)
+	}
+}
+
+func TestHandlersReportsUnwrappedHandlerForUseWithTimeout(t *testing.T) {
+	n := New()
+	slow := HandlerFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {})
+	n.UseWithTimeout(time.Second, slow)
+
+	handlers := n.Handlers()
+	if len(handlers) != 1 {
+		t.Fatalf("Handlers() = %v, want 1 entry", handlers)
+	}
+	if _, ok := handlers[0].(timeoutHandler); ok {
+		t.Errorf("Handlers()[0] reported the timeoutHandler adapter, want the wrapped Handler")
+	}
+	if _, ok := handlers[0].(HandlerFunc); !ok {
+		t.Errorf("Handlers()[0] = %T, want the underlying HandlerFunc added via UseWithTimeout", handlers[0])
+	}
+}
+
+func TestNewWithContextRejectsOnceDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	n := NewWithContext(ctx)
+	cancel()
+
+	rw := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	n.ServeHTTP(rw, r)
+
+	if rw.Code != http.StatusGatewayTimeout {
+		t.Errorf("status = %d, want %d", rw.Code, http.StatusGatewayTimeout)
+	}
+}
+
+func TestShutdownCancelsInFlightRequestContext(t *testing.T) {
+	done := make(chan struct{})
+	n := NewWithContext(context.Background())
+	n.Use(HandlerFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		<-r.Context().Done()
+		close(done)
+	}))
+
+	rw := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	go n.ServeHTTP(rw, r)
+
+	// Give ServeHTTP a moment to start dispatching before Shutdown runs.
+	time.Sleep(10 * time.Millisecond)
+	if err := n.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("in-flight handler never observed Shutdown's cancellation")
+	}
+}
@@ -0,0 +1,103 @@
+package negroni
+
+import (
+	"net/http"
+	"strings"
+)
+
+// groupEntry associates a path prefix with a child Negroni stack.
+type groupEntry struct {
+	prefix string
+	child  *Negroni
+}
+
+// groupDispatcher is inserted once into the parent's own middleware chain
+// the first time Group is called. At request time it picks the longest
+// prefix registered on the parent that matches the request path, splices
+// that single child's handlers in, and falls through to the parent's
+// remaining chain (next) either way. This is what makes Group additive: the
+// parent's earlier middleware already ran to reach this point, and
+// whatever follows the group in the parent chain still runs afterwards, so
+// a group only needs to carry the extra, subtree-specific middleware
+// rather than re-declaring the whole stack.
+type groupDispatcher struct {
+	n *Negroni
+}
+
+func (d *groupDispatcher) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	child := d.n.matchGroup(r.URL.Path)
+	if child == nil {
+		next(rw, r)
+		return
+	}
+	buildChain(child.handlers, next).ServeHTTP(rw, r)
+}
+
+// Group returns a child *Negroni whose Use/UseFunc/UseHandler calls append
+// middleware that only runs for requests whose URL path starts with prefix
+// at a path-segment boundary (e.g. "/api" matches "/api" and "/api/v1", but
+// not "/apidocs"). When more than one registered prefix matches a request,
+// the longest one wins. Calling Group again with a prefix already
+// registered on n returns the same child rather than creating a second,
+// competing group.
+func (n *Negroni) Group(prefix string) *Negroni {
+	if child := n.groupFor(prefix); child != nil {
+		return child
+	}
+	child := New()
+	n.groups = append(n.groups, &groupEntry{prefix: prefix, child: child})
+	if !n.groupDispatched {
+		n.Use(&groupDispatcher{n: n})
+		n.groupDispatched = true
+	}
+	return child
+}
+
+// UseOn is a convenience for Group(prefix).Use(h); it mounts h under prefix
+// without requiring callers to hold onto the child Negroni.
+func (n *Negroni) UseOn(prefix string, h Handler) {
+	n.Group(prefix).Use(h)
+}
+
+// groupFor returns the child Negroni already registered for prefix, or nil
+// if no group has claimed it yet.
+func (n *Negroni) groupFor(prefix string) *Negroni {
+	for _, g := range n.groups {
+		if g.prefix == prefix {
+			return g.child
+		}
+	}
+	return nil
+}
+
+// matchGroup returns the child Negroni registered for the longest prefix
+// matching path at a path-segment boundary, or nil if none match.
+func (n *Negroni) matchGroup(path string) *Negroni {
+	var best *groupEntry
+	for _, g := range n.groups {
+		if !pathHasPrefixBoundary(path, g.prefix) {
+			continue
+		}
+		if best == nil || len(g.prefix) > len(best.prefix) {
+			best = g
+		}
+	}
+	if best == nil {
+		return nil
+	}
+	return best.child
+}
+
+// pathHasPrefixBoundary reports whether path starts with prefix at a
+// path-segment boundary: either path equals prefix exactly, prefix already
+// ends in "/", or the character in path right after prefix is "/". This
+// keeps Group("/api") from also matching "/apidocs".
+func pathHasPrefixBoundary(path, prefix string) bool {
+	if !strings.HasPrefix(path, prefix) {
+		return false
+	}
+	if len(path) == len(prefix) || strings.HasSuffix(prefix, "/") {
+		return true
+	}
+	return path[len(prefix)] == '/'
+}
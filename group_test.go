@@ -0,0 +1,157 @@
+package negroni
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func markerHandler(name string, out *[]string) Handler {
+	return HandlerFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		*out = append(*out, name)
+		next(rw, r)
+	})
+}
+
+func TestGroupPathSegmentBoundary(t *testing.T) {
+	var fired []string
+	n := New()
+	n.Group("/api").Use(markerHandler("api", &fired))
+
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{"/api", []string{"api"}},
+		{"/api/v1/widgets", []string{"api"}},
+		{"/apidocs", nil},
+		{"/apiclient", nil},
+		{"/other", nil},
+	}
+
+	for _, tt := range tests {
+		fired = nil
+		rw := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		n.ServeHTTP(rw, r)
+		if len(fired) != len(tt.want) {
+			t.Errorf("path %q: fired %v, want %v", tt.path, fired, tt.want)
+			continue
+		}
+		for i := range fired {
+			if fired[i] != tt.want[i] {
+				t.Errorf("path %q: fired %v, want %v", tt.path, fired, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestGroupLongestPrefixWins(t *testing.T) {
+	var fired []string
+	n := New()
+	n.Group("/a").Use(markerHandler("a", &fired))
+	n.Group("/a/b").Use(markerHandler("a/b", &fired))
+
+	rw := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/a/b/x", nil)
+	n.ServeHTTP(rw, r)
+
+	if len(fired) != 1 || fired[0] != "a/b" {
+		t.Errorf("fired %v, want only [a/b]", fired)
+	}
+}
+
+func TestGroupReturnsSameChildForSamePrefix(t *testing.T) {
+	n := New()
+	first := n.Group("/api")
+	second := n.Group("/api")
+	if first != second {
+		t.Error("Group called twice with the same prefix returned different children")
+	}
+}
+
+func TestUseOnMountsUnderPrefix(t *testing.T) {
+	var fired []string
+	n := New()
+	n.UseOn("/admin", markerHandler("admin", &fired))
+
+	rw := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/admin/users", nil)
+	n.ServeHTTP(rw, r)
+
+	if len(fired) != 1 || fired[0] != "admin" {
+		t.Errorf("fired %v, want only [admin]", fired)
+	}
+}
+
+func TestHandlersSplicesMatchingGroup(t *testing.T) {
+	var fired []string
+	n := New()
+	top := markerHandler("top", &fired)
+	api := markerHandler("api", &fired)
+	n.Use(top)
+	n.Group("/api").Use(api)
+
+	handlers := n.Handlers("/api/widgets")
+	if len(handlers) != 2 {
+		t.Fatalf("Handlers(%q) = %v, want 2 entries", "/api/widgets", handlers)
+	}
+	if !sameHandlerFunc(handlers[0], top) {
+		t.Errorf("Handlers(%q)[0] = %v, want the top-level handler", "/api/widgets", handlers[0])
+	}
+	if !sameHandlerFunc(handlers[1], api) {
+		t.Errorf("Handlers(%q)[1] = %v, want the spliced-in group handler", "/api/widgets", handlers[1])
+	}
+
+	// sameHandlerFunc can't tell two markerHandler results apart, so run the
+	// reported handlers to check which marker each one is.
+	fired = nil
+	for _, h := range handlers {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/widgets", nil), func(http.ResponseWriter, *http.Request) {})
+	}
+	if !reflect.DeepEqual(fired, []string{"top", "api"}) {
+		t.Errorf("Handlers(%q) ran markers %v, want [top api]", "/api/widgets", fired)
+	}
+}
+
+// sameHandlerFunc reports whether a and b are both HandlerFunc values with
+// the same underlying code pointer. HandlerFunc wraps a func, which Go
+// doesn't allow comparing with ==, so this compares code pointers instead;
+// closures created by the same function literal (e.g. two markerHandler
+// results) share a code pointer and so compare equal.
+func sameHandlerFunc(a, b Handler) bool {
+	af, aok := a.(HandlerFunc)
+	bf, bok := b.(HandlerFunc)
+	if !aok || !bok {
+		return false
+	}
+	return reflect.ValueOf(af).Pointer() == reflect.ValueOf(bf).Pointer()
+}
+
+func TestHandlersOmitsGroupWhenPathDoesntMatch(t *testing.T) {
+	var fired []string
+	n := New()
+	top := markerHandler("top", &fired)
+	n.Use(top)
+	n.Group("/api").Use(markerHandler("api", &fired))
+
+	handlers := n.Handlers("/other")
+	if len(handlers) != 1 || !sameHandlerFunc(handlers[0], top) {
+		t.Errorf("Handlers(%q) = %v, want only the top-level handler", "/other", handlers)
+	}
+}
+
+func TestHandlersWithoutPathReportsGroupDispatcherItself(t *testing.T) {
+	n := New()
+	n.Group("/api").Use(markerHandler("api", new([]string)))
+
+	handlers := n.Handlers()
+	if len(handlers) != 1 {
+		t.Fatalf("Handlers() = %v, want 1 entry", handlers)
+	}
+	if _, ok := handlers[0].(*groupDispatcher); !ok {
+		t.Errorf("Handlers()[0] = %T, want the groupDispatcher itself", handlers[0])
+	}
+}
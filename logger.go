@@ -0,0 +1,186 @@
+package negroni
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"time"
+)
+
+// Logger is the logging backend used by RequestLogger and by Negroni's own
+// Run/RunTLS/RunServer/RunServerTLS startup and error messages. Embedding
+// applications can implement it to forward Negroni's logging into whatever
+// observability stack they already use.
+type Logger interface {
+	Infof(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+	With(fields ...interface{}) Logger
+}
+
+// RequestLogger is the request/response logging middleware used by
+// Classic(). It reports one line per request through a pluggable Logger
+// backend; see SetLogger to swap it for NewJSONLogger, NewCLFLogger, etc.
+type RequestLogger struct {
+	ALogger Logger
+}
+
+// NewLogger returns a new RequestLogger that writes to os.Stdout using the
+// default text Logger.
+func NewLogger() *RequestLogger {
+	return &RequestLogger{ALogger: newTextLogger()}
+}
+
+// SetLogger swaps the Logger backend used by this middleware.
+func (l *RequestLogger) SetLogger(logger Logger) {
+	l.ALogger = logger
+}
+
+func (l *RequestLogger) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	start := time.Now()
+	next(rw, r)
+
+	res := rw.(ResponseWriter)
+	l.ALogger.With(
+		"method", r.Method,
+		"path", r.URL.Path,
+		"status", res.Status(),
+		"bytes", res.Size(),
+		"duration", time.Since(start),
+		"remote_addr", r.RemoteAddr,
+		"request_id", RequestIDFromContext(r.Context()),
+	).Infof("%s %s", r.Method, r.URL.Path)
+}
+
+// textLogger is the default Logger, preserving Negroni's historical
+// "[negroni] " prefixed stdout output.
+type textLogger struct {
+	out    *log.Logger
+	fields []interface{}
+}
+
+func newTextLogger() Logger {
+	return &textLogger{out: log.New(os.Stdout, "[negroni] ", 0)}
+}
+
+func (t *textLogger) Infof(format string, args ...interface{}) {
+	t.out.Printf(t.withFields(format), args...)
+}
+
+func (t *textLogger) Errorf(format string, args ...interface{}) {
+	t.out.Printf("ERROR: "+t.withFields(format), args...)
+}
+
+func (t *textLogger) With(fields ...interface{}) Logger {
+	return &textLogger{out: t.out, fields: append(append([]interface{}{}, t.fields...), fields...)}
+}
+
+func (t *textLogger) withFields(format string) string {
+	if len(t.fields) == 0 {
+		return format
+	}
+	suffix := ""
+	for i := 0; i+1 < len(t.fields); i += 2 {
+		suffix += fmt.Sprintf(" %v=%v", t.fields[i], t.fields[i+1])
+	}
+	return format + suffix
+}
+
+// jsonLogger is a Logger that emits one JSON object per call to Infof or
+// Errorf, merging in any fields accumulated via With. Used to pipe request
+// events into log-aggregation stacks that expect structured JSON.
+type jsonLogger struct {
+	out    io.Writer
+	fields map[string]interface{}
+}
+
+// NewJSONLogger returns a Logger that writes one JSON object per line to w
+// (os.Stdout if w is nil).
+func NewJSONLogger(w io.Writer) Logger {
+	if w == nil {
+		w = os.Stdout
+	}
+	return &jsonLogger{out: w}
+}
+
+func (j *jsonLogger) Infof(format string, args ...interface{}) {
+	j.emit("info", fmt.Sprintf(format, args...))
+}
+
+func (j *jsonLogger) Errorf(format string, args ...interface{}) {
+	j.emit("error", fmt.Sprintf(format, args...))
+}
+
+func (j *jsonLogger) With(fields ...interface{}) Logger {
+	return &jsonLogger{out: j.out, fields: mergeFields(j.fields, fields)}
+}
+
+func (j *jsonLogger) emit(level, message string) {
+	event := make(map[string]interface{}, len(j.fields)+3)
+	for k, v := range j.fields {
+		event[k] = v
+	}
+	event["time"] = time.Now().Format(time.RFC3339Nano)
+	event["level"] = level
+	event["message"] = message
+
+	enc := json.NewEncoder(j.out)
+	_ = enc.Encode(event)
+}
+
+// clfLogger renders the fields accumulated via With as Apache/ELB "Common
+// Log Format" access log lines.
+type clfLogger struct {
+	out    io.Writer
+	fields map[string]interface{}
+}
+
+// NewCLFLogger returns a Logger that renders request fields set via With as
+// Common Log Format lines to w (os.Stdout if w is nil).
+func NewCLFLogger(w io.Writer) Logger {
+	if w == nil {
+		w = os.Stdout
+	}
+	return &clfLogger{out: w}
+}
+
+// NewALSLogger is an alias for NewCLFLogger, for callers migrating from
+// ELB-style "access log service" logging, which shares the same line format.
+func NewALSLogger(w io.Writer) Logger {
+	return NewCLFLogger(w)
+}
+
+func (c *clfLogger) Infof(format string, args ...interface{}) {
+	if len(c.fields) == 0 {
+		fmt.Fprintf(c.out, format+"\n", args...)
+		return
+	}
+	fmt.Fprintf(c.out, "%v - - [%s] %q %v %v\n",
+		c.fields["remote_addr"],
+		time.Now().Format("02/Jan/2006:15:04:05 -0700"),
+		fmt.Sprintf("%v %v HTTP/1.1", c.fields["method"], c.fields["path"]),
+		c.fields["status"], c.fields["bytes"])
+}
+
+func (c *clfLogger) Errorf(format string, args ...interface{}) {
+	fmt.Fprintf(c.out, format+"\n", args...)
+}
+
+func (c *clfLogger) With(fields ...interface{}) Logger {
+	return &clfLogger{out: c.out, fields: mergeFields(c.fields, fields)}
+}
+
+func mergeFields(base map[string]interface{}, fields []interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(base)+len(fields)/2)
+	for k, v := range base {
+		merged[k] = v
+	}
+	for i := 0; i+1 < len(fields); i += 2 {
+		if key, ok := fields[i].(string); ok {
+			merged[key] = fields[i+1]
+		}
+	}
+	return merged
+}
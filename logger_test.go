@@ -0,0 +1,81 @@
+package negroni
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestJSONLoggerEmitsRequestFields(t *testing.T) {
+	var buf bytes.Buffer
+	n := New()
+	rl := NewLogger()
+	rl.SetLogger(NewJSONLogger(&buf))
+	n.Use(rl)
+	n.UseFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		rw.WriteHeader(http.StatusTeapot)
+		rw.Write([]byte("hi"))
+	})
+
+	rw := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/brew", nil)
+	n.ServeHTTP(rw, r)
+
+	var event map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &event); err != nil {
+		t.Fatalf("output is not valid JSON: %v (%q)", err, buf.String())
+	}
+
+	if event["method"] != "GET" {
+		t.Errorf("method = %v, want GET", event["method"])
+	}
+	if event["path"] != "/brew" {
+		t.Errorf("path = %v, want /brew", event["path"])
+	}
+	if status, _ := event["status"].(float64); int(status) != http.StatusTeapot {
+		t.Errorf("status = %v, want %d", event["status"], http.StatusTeapot)
+	}
+	if size, _ := event["bytes"].(float64); int(size) != 2 {
+		t.Errorf("bytes = %v, want 2", event["bytes"])
+	}
+}
+
+func TestSetLoggerWiresExistingRequestLoggers(t *testing.T) {
+	var buf bytes.Buffer
+	n := New()
+	n.Use(NewLogger())
+	n.SetLogger(NewJSONLogger(&buf))
+	n.UseFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		rw.WriteHeader(http.StatusOK)
+	})
+
+	rw := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	n.ServeHTTP(rw, r)
+
+	if buf.Len() == 0 {
+		t.Fatal("SetLogger did not wire the JSON logger into the already-registered RequestLogger")
+	}
+	var event map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &event); err != nil {
+		t.Fatalf("output is not valid JSON: %v (%q)", err, buf.String())
+	}
+}
+
+func TestTextLoggerAppendsFields(t *testing.T) {
+	var buf bytes.Buffer
+	l := &textLogger{out: log.New(&buf, "", 0)}
+	l.With("request_id", "abc123").Infof("did the thing")
+
+	out := buf.String()
+	if !strings.Contains(out, "did the thing") {
+		t.Errorf("output %q missing message", out)
+	}
+	if !strings.Contains(out, "request_id=abc123") {
+		t.Errorf("output %q missing request_id field", out)
+	}
+}
@@ -1,7 +1,8 @@
 package negroni
 
 import (
-	"log"
+	"context"
+	"errors"
 	"net/http"
 	"os"
 	"time"
@@ -48,8 +49,14 @@ func Wrap(handler http.Handler) Handler {
 // Negroni middleware is evaluated in the order that they are added to the stack using
 // the Use and UseHandler methods.
 type Negroni struct {
-	middleware middleware
-	handlers   []Handler
+	middleware      middleware
+	handlers        []Handler
+	groups          []*groupEntry
+	groupDispatched bool
+	ctx             context.Context
+	cancel          context.CancelFunc
+	servers         []*http.Server
+	logger          Logger
 }
 
 // New returns a new Negroni instance with no middleware preconfigured.
@@ -60,6 +67,19 @@ func New(handlers ...Handler) *Negroni {
 	}
 }
 
+// NewWithContext is like New, but ties the returned Negroni to ctx: once ctx
+// is done (or Shutdown is called), ServeHTTP stops dispatching to the
+// middleware chain and responds 504 instead.
+func NewWithContext(ctx context.Context, handlers ...Handler) *Negroni {
+	ctx, cancel := context.WithCancel(ctx)
+	return &Negroni{
+		handlers:   handlers,
+		middleware: build(handlers),
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+}
+
 // Classic returns a new Negroni instance with the default middleware already
 // in the stack.
 //
@@ -71,15 +91,81 @@ func Classic() *Negroni {
 }
 
 func (n *Negroni) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	// Seed the panic holder (see recovery.go) on r before anything else
+	// gets a chance to rebind it via r.WithContext (the n.ctx branch below,
+	// RequestID, ...), so a Recovery anywhere in the chain fills in a
+	// holder that's still reachable from this exact *http.Request, the one
+	// the caller of ServeHTTP is holding onto.
+	installPanicHolder(r)
+
+	if n.ctx != nil {
+		if n.ctx.Err() != nil {
+			rw.WriteHeader(http.StatusGatewayTimeout)
+			return
+		}
+		ctx, cancel := mergeCancel(r.Context(), n.ctx)
+		defer cancel()
+		r = r.WithContext(ctx)
+	}
 	n.middleware.ServeHTTP(NewResponseWriter(rw), r)
 }
 
+// mergeCancel returns a context derived from parent that is also cancelled
+// as soon as root is done, so a NewWithContext root cancelled by Shutdown
+// unblocks handlers that are already in flight instead of only affecting
+// requests that haven't started dispatching yet.
+func mergeCancel(parent, root context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(parent)
+	go func() {
+		select {
+		case <-root.Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
+
+// UseWithTimeout adds a Handler onto the middleware stack like Use, but
+// gives it (and everything further down the chain) a request context that is
+// cancelled once d elapses. If that deadline has passed when h calls next or
+// returns, the rest of the chain is skipped and a 504 is written if nothing
+// has been written yet. A handler that ignores its context is not
+// interrupted.
+func (n *Negroni) UseWithTimeout(d time.Duration, h Handler) {
+	n.Use(timeoutHandler{handler: h, timeout: d})
+}
+
+// Shutdown cancels the context passed to NewWithContext, if any, and
+// gracefully shuts down every *http.Server started by this Negroni via Run,
+// RunTLS, RunServer or RunServerTLS, delegating to http.Server.Shutdown.
+func (n *Negroni) Shutdown(ctx context.Context) error {
+	if n.cancel != nil {
+		n.cancel()
+	}
+	var err error
+	for _, server := range n.servers {
+		if shutdownErr := server.Shutdown(ctx); shutdownErr != nil && err == nil {
+			err = shutdownErr
+		}
+	}
+	return err
+}
+
+func (n *Negroni) trackServer(server *http.Server) {
+	n.servers = append(n.servers, server)
+}
+
 // Use adds a Handler onto the middleware stack. Handlers are invoked in the order they are added to a Negroni.
 func (n *Negroni) Use(handler Handler) {
 	if handler == nil {
 		panic("handler cannot be nil")
 	}
 
+	if rl, ok := handler.(*RequestLogger); ok && n.logger != nil {
+		rl.SetLogger(n.logger)
+	}
+
 	n.handlers = append(n.handlers, handler)
 	n.middleware = build(n.handlers)
 }
@@ -102,42 +188,111 @@ func (n *Negroni) UseHandlerFunc(handlerFunc func(rw http.ResponseWriter, r *htt
 // Run is a convenience function that runs the negroni stack as an HTTP
 // server. The addr string takes the same format as http.ListenAndServe.
 func (n *Negroni) Run(addr string) {
-	l := log.New(os.Stdout, "[negroni] ", 0)
-	l.Printf("listening on %s", addr)
+	l := n.log()
+	l.Infof("listening on %s", addr)
 	server := &http.Server{Addr: addr, Handler: n, ReadTimeout: 10 * time.Second,
 		WriteTimeout: 10 * time.Second, MaxHeaderBytes: 1 << 16}
-	l.Fatal(server.ListenAndServe())
+	n.trackServer(server)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		l.Errorf("%v", err)
+		os.Exit(1)
+	}
 }
 
 // Wrapper for http.ListenAndServeTLS to add https support
 func (n *Negroni) RunTLS(addr string, certFile string, keyFile string) {
-	l := log.New(os.Stdout, "[negroni] ", 0)
-	l.Printf("listening on %s, certFile at %s, keyFile at %s", addr, certFile, keyFile)
+	l := n.log()
+	l.Infof("listening on %s, certFile at %s, keyFile at %s", addr, certFile, keyFile)
 	server := &http.Server{Addr: addr, Handler: n, ReadTimeout: 10 * time.Second,
 		WriteTimeout: 10 * time.Second, MaxHeaderBytes: 1 << 16}
-	l.Fatal(server.ListenAndServeTLS(certFile, keyFile))
+	n.trackServer(server)
+	if err := server.ListenAndServeTLS(certFile, keyFile); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		l.Errorf("%v", err)
+		os.Exit(1)
+	}
 }
 
 // RunServer behaves in a similar fashion as Run except that it takes http.Server
 // as the argument which can be customised according to client's needs.
 func (n *Negroni) RunServer(server *http.Server) {
-	l := log.New(os.Stdout, "[negroni] ", 0)
-	l.Printf("listening on %s", server.Addr)
+	l := n.log()
+	l.Infof("listening on %s", server.Addr)
 	server.Handler = n
-	l.Fatal(server.ListenAndServe())
+	n.trackServer(server)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		l.Errorf("%v", err)
+		os.Exit(1)
+	}
 }
 
 // Run:RunServer::RunTLS:RunServerTLS
 func (n *Negroni) RunServerTLS(server *http.Server, certFile string, keyFile string) {
-	l := log.New(os.Stdout, "[negroni] ", 0)
-	l.Printf("listening on %s, certFile at %s, keyFile at %s", server.Addr, certFile, keyFile)
+	l := n.log()
+	l.Infof("listening on %s, certFile at %s, keyFile at %s", server.Addr, certFile, keyFile)
 	server.Handler = n
-	l.Fatal(server.ListenAndServeTLS(certFile, keyFile))
+	n.trackServer(server)
+	if err := server.ListenAndServeTLS(certFile, keyFile); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		l.Errorf("%v", err)
+		os.Exit(1)
+	}
 }
 
-// Returns a list of all the handlers in the current Negroni middleware chain.
-func (n *Negroni) Handlers() []Handler {
-	return n.handlers
+// SetLogger overrides the Logger used for the "listening on"/error messages
+// emitted by Run, RunTLS, RunServer and RunServerTLS, and pushes l to every
+// RequestLogger already registered directly in this Negroni's chain (e.g.
+// via Classic, New or Use), so request logs and startup logs share one
+// backend. RequestLogger instances added directly via Use after SetLogger
+// pick up l too. A RequestLogger wrapped by UseIf, UseUnless or
+// UseWithTimeout, or added to a Group's child, is not updated.
+func (n *Negroni) SetLogger(l Logger) {
+	n.logger = l
+	for _, h := range n.handlers {
+		if rl, ok := h.(*RequestLogger); ok {
+			rl.SetLogger(l)
+		}
+	}
+}
+
+// log returns n's Logger, creating the default stdout Logger the first time
+// it's needed.
+func (n *Negroni) log() Logger {
+	if n.logger == nil {
+		n.logger = newTextLogger()
+	}
+	return n.logger
+}
+
+// Handlers returns a list of all the handlers in the current Negroni
+// middleware chain. If path is given, the groupDispatcher registered by
+// Group (if any) is expanded in place: the longest-matching group's
+// handlers are spliced in for path, or omitted if no group matches, so
+// callers can inspect the effective chain for a given request path.
+func (n *Negroni) Handlers(path ...string) []Handler {
+	var matchPath string
+	var filterGroups bool
+	if len(path) > 0 {
+		matchPath = path[0]
+		filterGroups = true
+	}
+
+	var handlers []Handler
+	for _, h := range n.handlers {
+		if _, ok := h.(*groupDispatcher); ok {
+			if filterGroups {
+				if child := n.matchGroup(matchPath); child != nil {
+					handlers = append(handlers, child.Handlers(path...)...)
+				}
+				continue
+			}
+			handlers = append(handlers, h)
+			continue
+		}
+		if u, ok := h.(unwrapper); ok {
+			h = u.Unwrap()
+		}
+		handlers = append(handlers, h)
+	}
+	return handlers
 }
 
 func build(handlers []Handler) middleware {
@@ -160,3 +315,17 @@ func voidMiddleware() middleware {
 		&middleware{},
 	}
 }
+
+// buildChain is like build, but the chain falls through to tail instead of
+// the usual no-op once handlers is exhausted, so a subchain (see
+// groupDispatcher) can be spliced into an outer chain instead of replacing it.
+func buildChain(handlers []Handler, tail http.HandlerFunc) middleware {
+	if len(handlers) == 0 {
+		return middleware{
+			HandlerFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) { tail(rw, r) }),
+			&middleware{},
+		}
+	}
+	next := buildChain(handlers[1:], tail)
+	return middleware{handlers[0], &next}
+}
@@ -0,0 +1,41 @@
+package negroni
+
+import "net/http"
+
+// unwrapper is implemented by internal Handler adapters (predicateHandler,
+// timeoutHandler) that wrap a user-supplied Handler, so Handlers() can
+// report the underlying Handler instead of the adapter.
+type unwrapper interface {
+	Unwrap() Handler
+}
+
+// predicateHandler wraps a Handler with a guard function; when the guard
+// returns false, control passes straight to next and the wrapped Handler
+// never runs.
+type predicateHandler struct {
+	handler Handler
+	guard   func(*http.Request) bool
+}
+
+func (p predicateHandler) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	if !p.guard(r) {
+		next(rw, r)
+		return
+	}
+	p.handler.ServeHTTP(rw, r, next)
+}
+
+func (p predicateHandler) Unwrap() Handler { return p.handler }
+
+// UseIf adds h onto the middleware stack like Use, but only invokes h for
+// requests where pred returns true; for every other request, control passes
+// straight to the next handler in the chain.
+func (n *Negroni) UseIf(pred func(*http.Request) bool, h Handler) {
+	n.Use(predicateHandler{handler: h, guard: pred})
+}
+
+// UseUnless is the inverse of UseIf: h runs for every request except those
+// where pred returns true.
+func (n *Negroni) UseUnless(pred func(*http.Request) bool, h Handler) {
+	n.Use(predicateHandler{handler: h, guard: func(r *http.Request) bool { return !pred(r) }})
+}
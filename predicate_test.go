@@ -0,0 +1,99 @@
+package negroni
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestUseIfRunsOnlyWhenPredicateTrue(t *testing.T) {
+	var ran bool
+	n := New()
+	n.UseIf(func(r *http.Request) bool { return r.URL.Path == "/admin" }, HandlerFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		ran = true
+		next(rw, r)
+	}))
+
+	rw := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/other", nil)
+	n.ServeHTTP(rw, r)
+	if ran {
+		t.Error("handler ran for a path the predicate rejects")
+	}
+
+	ran = false
+	rw = httptest.NewRecorder()
+	r = httptest.NewRequest(http.MethodGet, "/admin", nil)
+	n.ServeHTTP(rw, r)
+	if !ran {
+		t.Error("handler did not run for a path the predicate accepts")
+	}
+}
+
+func TestUseUnlessRunsOnlyWhenPredicateFalse(t *testing.T) {
+	var ran bool
+	n := New()
+	n.UseUnless(func(r *http.Request) bool { return r.URL.Path == "/health" }, HandlerFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		ran = true
+		next(rw, r)
+	}))
+
+	rw := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/health", nil)
+	n.ServeHTTP(rw, r)
+	if ran {
+		t.Error("handler ran for a path UseUnless should have skipped")
+	}
+
+	ran = false
+	rw = httptest.NewRecorder()
+	r = httptest.NewRequest(http.MethodGet, "/other", nil)
+	n.ServeHTTP(rw, r)
+	if !ran {
+		t.Error("handler did not run for a path UseUnless should have allowed")
+	}
+}
+
+func TestUseIfChainContinuesWhenSkipped(t *testing.T) {
+	var calledNext bool
+	n := New()
+	n.UseIf(func(r *http.Request) bool { return false }, HandlerFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		t.Fatal("wrapped handler should not have run")
+	}))
+	n.UseFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		calledNext = true
+	})
+
+	rw := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	n.ServeHTTP(rw, r)
+
+	if !calledNext {
+		t.Error("chain did not continue to the next handler when the predicate was false")
+	}
+}
+
+func TestHandlersReportsUnwrappedHandlerForUseIfAndUseUnless(t *testing.T) {
+	n := New()
+	admin := HandlerFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {})
+	health := HandlerFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {})
+	n.UseIf(func(r *http.Request) bool { return true }, admin)
+	n.UseUnless(func(r *http.Request) bool { return true }, health)
+
+	handlers := n.Handlers()
+	if len(handlers) != 2 {
+		t.Fatalf("Handlers() = %v, want 2 entries", handlers)
+	}
+	if _, ok := handlers[0].(predicateHandler); ok {
+		t.Errorf("Handlers()[0] reported the predicateHandler adapter, want the wrapped Handler")
+	}
+	if _, ok := handlers[0].(HandlerFunc); !ok {
+		t.Errorf("Handlers()[0] = %T, want the underlying HandlerFunc added via UseIf", handlers[0])
+	}
+	if _, ok := handlers[1].(predicateHandler); ok {
+		t.Errorf("Handlers()[1] reported the predicateHandler adapter, want the wrapped Handler")
+	}
+	if _, ok := handlers[1].(HandlerFunc); !ok {
+		t.Errorf("Handlers()[1] = %T, want the underlying HandlerFunc added via UseUnless", handlers[1])
+	}
+}
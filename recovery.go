@@ -0,0 +1,219 @@
+package negroni
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"runtime"
+	"strings"
+)
+
+// PanicInfo carries everything captured when Recovery intercepts a panic:
+// the recovered value, a snapshot of the panicking goroutine's stack, and
+// the request id (see RequestID) of the request that panicked, if any.
+type PanicInfo struct {
+	RecoveredPanic interface{}
+	Stack          []byte
+	RequestID      string
+}
+
+// PanicHandler is invoked by Recovery, after it has written the error
+// response via Formatter, letting callers observe or report the panic (e.g.
+// to an error-tracking service) without having to reimplement Formatter.
+type PanicHandler func(rw http.ResponseWriter, r *http.Request, info PanicInfo)
+
+// Formatter renders a PanicInfo as an HTTP response, including the status
+// code and any headers.
+type Formatter interface {
+	FormatPanic(rw http.ResponseWriter, r *http.Request, info PanicInfo)
+}
+
+// FormatterFunc is an adapter to allow ordinary functions to be used as a
+// Formatter.
+type FormatterFunc func(rw http.ResponseWriter, r *http.Request, info PanicInfo)
+
+func (f FormatterFunc) FormatPanic(rw http.ResponseWriter, r *http.Request, info PanicInfo) {
+	f(rw, r, info)
+}
+
+type panicErrorKeyType int
+
+const panicErrorKey panicErrorKeyType = 0
+
+// PanicError is the sentinel error Recovery records for a recovered panic,
+// so code holding the same *http.Request (e.g. an http.Handler that wraps
+// Negroni and calls PanicErrorFromContext after n.ServeHTTP returns) can
+// record it as a 500 with its full stack, rather than a bare status code.
+type PanicError struct {
+	Recovered interface{}
+	Stack     []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Recovered)
+}
+
+// panicHolder is a mutable cell installed into the request context before
+// Recovery dispatches to next. Because it's a pointer, any code sharing the
+// same underlying context sees the PanicError Recovery fills in on recover,
+// even though context.Context itself is immutable and a deferred
+// r.WithContext reassignment can't flow back up through already-returned
+// callers.
+type panicHolder struct {
+	err *PanicError
+}
+
+// installPanicHolder ensures r's context carries a *panicHolder cell,
+// reusing one already installed (typically by Negroni.ServeHTTP, anchoring
+// it to the *http.Request the caller of ServeHTTP is actually holding)
+// instead of creating a fresh one that only the current, possibly
+// already-rebound *http.Request would see. Middleware further down the
+// chain that calls r.WithContext (RequestID, NewWithContext's own
+// reassignment, ...) derives its context from this one, so the same holder
+// pointer remains reachable from every *http.Request in play for this
+// request, including the original one.
+func installPanicHolder(r *http.Request) *panicHolder {
+	if holder, ok := r.Context().Value(panicErrorKey).(*panicHolder); ok {
+		return holder
+	}
+	holder := &panicHolder{}
+	*r = *r.WithContext(context.WithValue(r.Context(), panicErrorKey, holder))
+	return holder
+}
+
+// PanicErrorFromContext returns the PanicError recorded by Recovery for the
+// current request, or nil if the request didn't panic (or never passed
+// through a Recovery).
+func PanicErrorFromContext(ctx context.Context) *PanicError {
+	holder, ok := ctx.Value(panicErrorKey).(*panicHolder)
+	if !ok {
+		return nil
+	}
+	return holder.err
+}
+
+// RecoveryOptions configures a Recovery middleware built with
+// NewRecoveryWithOptions. A zero StackSize, Formatter or Logger falls back
+// to the same default NewRecovery uses; PrintStack, StackAll and OnPanic are
+// used as given, so a zero PrintStack stays false even though NewRecovery
+// sets it to true.
+type RecoveryOptions struct {
+	PrintStack bool
+	StackAll   bool
+	StackSize  int
+	Formatter  Formatter
+	Logger     Logger
+	OnPanic    PanicHandler
+}
+
+// Recovery is a middleware that recovers from panics anywhere further down
+// the chain, logs them, renders an error response via Formatter, and
+// propagates a PanicError into the request context.
+type Recovery struct {
+	PrintStack bool
+	StackAll   bool
+	StackSize  int
+	Formatter  Formatter
+	Logger     Logger
+	OnPanic    PanicHandler
+}
+
+// NewRecovery returns a new Recovery instance with sane defaults: it prints
+// the panicking goroutine's stack to stdout and renders a plain HTML 500.
+func NewRecovery() *Recovery {
+	return NewRecoveryWithOptions(RecoveryOptions{
+		PrintStack: true,
+	})
+}
+
+// NewRecoveryWithOptions returns a Recovery middleware configured by opts.
+func NewRecoveryWithOptions(opts RecoveryOptions) *Recovery {
+	if opts.StackSize == 0 {
+		opts.StackSize = 1024 * 8
+	}
+	if opts.Formatter == nil {
+		opts.Formatter = htmlFormatter{}
+	}
+	if opts.Logger == nil {
+		opts.Logger = newTextLogger()
+	}
+	return &Recovery{
+		PrintStack: opts.PrintStack,
+		StackAll:   opts.StackAll,
+		StackSize:  opts.StackSize,
+		Formatter:  opts.Formatter,
+		Logger:     opts.Logger,
+		OnPanic:    opts.OnPanic,
+	}
+}
+
+func (rec *Recovery) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	// Reuse (or, used outside a Negroni, install) a holder on r before
+	// dispatching, so filling it in on recover (below) is visible to
+	// anything sharing this request's context, including code outside the
+	// middleware chain entirely (e.g. an http.Handler wrapping Negroni).
+	holder := installPanicHolder(r)
+
+	defer func() {
+		recovered := recover()
+		if recovered == nil {
+			return
+		}
+
+		stack := make([]byte, rec.StackSize)
+		stack = stack[:runtime.Stack(stack, rec.StackAll)]
+
+		holder.err = &PanicError{Recovered: recovered, Stack: stack}
+
+		info := PanicInfo{
+			RecoveredPanic: recovered,
+			Stack:          stack,
+			RequestID:      RequestIDFromContext(r.Context()),
+		}
+
+		if rec.PrintStack {
+			rec.Logger.Errorf("PANIC: %v\n%s", recovered, stack)
+		} else {
+			rec.Logger.Errorf("PANIC: %v", recovered)
+		}
+
+		rec.Formatter.FormatPanic(rw, r, info)
+
+		if rec.OnPanic != nil {
+			rec.OnPanic(rw, r, info)
+		}
+	}()
+
+	next(rw, r)
+}
+
+// htmlFormatter is the default Formatter: a minimal HTML 500 page, falling
+// back to JSON when the request's Accept header asks for it.
+type htmlFormatter struct{}
+
+func (htmlFormatter) FormatPanic(rw http.ResponseWriter, r *http.Request, info PanicInfo) {
+	if strings.Contains(r.Header.Get("Accept"), "application/json") {
+		jsonFormatter{}.FormatPanic(rw, r, info)
+		return
+	}
+	rw.Header().Set("Content-Type", "text/html; charset=utf-8")
+	rw.WriteHeader(http.StatusInternalServerError)
+	fmt.Fprintf(rw, "<h1>Internal Server Error</h1><pre>%s</pre>", html.EscapeString(fmt.Sprintf("%v", info.RecoveredPanic)))
+}
+
+// jsonFormatter renders the panic as a JSON error body.
+type jsonFormatter struct{}
+
+func (jsonFormatter) FormatPanic(rw http.ResponseWriter, r *http.Request, info PanicInfo) {
+	rw.Header().Set("Content-Type", "application/json; charset=utf-8")
+	rw.WriteHeader(http.StatusInternalServerError)
+	json.NewEncoder(rw).Encode(struct {
+		Error     string `json:"error"`
+		RequestID string `json:"request_id,omitempty"`
+	}{
+		Error:     fmt.Sprintf("%v", info.RecoveredPanic),
+		RequestID: info.RequestID,
+	})
+}
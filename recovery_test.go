@@ -0,0 +1,137 @@
+package negroni
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRecoveryWritesInternalServerError(t *testing.T) {
+	n := New()
+	n.Use(NewRecovery())
+	n.UseFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		panic("boom")
+	})
+
+	rw := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	n.ServeHTTP(rw, r)
+
+	if rw.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", rw.Code, http.StatusInternalServerError)
+	}
+}
+
+func TestRecoveryPopulatesPanicErrorFromContext(t *testing.T) {
+	n := New()
+	n.Use(NewRecovery())
+
+	var r *http.Request
+	n.UseFunc(func(rw http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+		r = req
+		panic("kaboom")
+	})
+
+	rw := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	n.ServeHTTP(rw, req)
+
+	if r == nil {
+		t.Fatal("wrapped handler never ran")
+	}
+	perr := PanicErrorFromContext(r.Context())
+	if perr == nil {
+		t.Fatal("PanicErrorFromContext returned nil after a recovered panic")
+	}
+	if perr.Recovered != "kaboom" {
+		t.Errorf("Recovered = %v, want %q", perr.Recovered, "kaboom")
+	}
+	if len(perr.Stack) == 0 {
+		t.Error("Stack was not captured")
+	}
+}
+
+func TestPanicErrorFromContextNilWithoutPanic(t *testing.T) {
+	n := New()
+	n.Use(NewRecovery())
+
+	var r *http.Request
+	n.UseFunc(func(rw http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+		r = req
+		rw.WriteHeader(http.StatusOK)
+	})
+
+	rw := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	n.ServeHTTP(rw, req)
+
+	if perr := PanicErrorFromContext(r.Context()); perr != nil {
+		t.Errorf("PanicErrorFromContext = %v, want nil when nothing panicked", perr)
+	}
+}
+
+func TestRecoveryPopulatesPanicErrorFromOriginalRequestWithRequestIDAhead(t *testing.T) {
+	// RequestID sits ahead of Recovery and calls next with r.WithContext(...),
+	// a distinct *http.Request from the one n.ServeHTTP receives. The panic
+	// must still be visible from that original pointer once ServeHTTP
+	// returns, not just from whatever request Recovery itself dispatched to.
+	n := New()
+	n.Use(NewRequestID())
+	n.Use(NewRecovery())
+	n.UseFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		panic("kaboom")
+	})
+
+	rw := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	n.ServeHTTP(rw, req)
+
+	perr := PanicErrorFromContext(req.Context())
+	if perr == nil {
+		t.Fatal("PanicErrorFromContext on the original request returned nil after a recovered panic")
+	}
+	if perr.Recovered != "kaboom" {
+		t.Errorf("Recovered = %v, want %q", perr.Recovered, "kaboom")
+	}
+}
+
+func TestPanicErrorFromContextNilWithoutRecovery(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if perr := PanicErrorFromContext(req.Context()); perr != nil {
+		t.Errorf("PanicErrorFromContext = %v, want nil outside any Recovery", perr)
+	}
+}
+
+func TestRecoveryJSONFormatterOnAcceptHeader(t *testing.T) {
+	n := New()
+	n.Use(NewRecovery())
+	n.UseFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		panic("oops")
+	})
+
+	rw := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Accept", "application/json")
+	n.ServeHTTP(rw, r)
+
+	if ct := rw.Header().Get("Content-Type"); ct != "application/json; charset=utf-8" {
+		t.Errorf("Content-Type = %q, want application/json", ct)
+	}
+}
+
+func TestRecoveryHTMLFormatterEscapesPanicValue(t *testing.T) {
+	n := New()
+	n.Use(NewRecovery())
+	n.UseFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		panic("<script>alert(1)</script>")
+	})
+
+	rw := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	n.ServeHTTP(rw, r)
+
+	if body := rw.Body.String(); strings.Contains(body, "<script>") {
+		t.Errorf("body contains unescaped panic value: %q", body)
+	}
+}
@@ -0,0 +1,50 @@
+package negroni
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net/http"
+)
+
+type requestIDKeyType int
+
+const requestIDKey requestIDKeyType = 0
+
+// RequestID is middleware that ensures every request carries a correlation
+// id: it honors an inbound X-Request-Id header, generating a UUIDv4 if none
+// was supplied, echoes it back on the response, and stores it on the
+// request context so downstream handlers (and RequestLogger) can pick it up
+// via RequestIDFromContext.
+type RequestID struct{}
+
+// NewRequestID returns a new RequestID instance.
+func NewRequestID() *RequestID {
+	return &RequestID{}
+}
+
+func (m *RequestID) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	id := r.Header.Get("X-Request-Id")
+	if id == "" {
+		id = newRequestID()
+	}
+	rw.Header().Set("X-Request-Id", id)
+	next(rw, r.WithContext(context.WithValue(r.Context(), requestIDKey, id)))
+}
+
+// RequestIDFromContext returns the request id stored by RequestID
+// middleware, or "" if none is present.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+func newRequestID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return ""
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
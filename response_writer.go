@@ -0,0 +1,83 @@
+package negroni
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// ResponseWriter wraps an http.ResponseWriter, recording the status code
+// and body size written so far so middleware further up the chain (e.g.
+// RequestLogger, timeoutHandler) can observe them without double-writing
+// a response of their own.
+type ResponseWriter interface {
+	http.ResponseWriter
+
+	// Status returns the status code passed to WriteHeader, or 0 if the
+	// response hasn't been written to yet.
+	Status() int
+	// Size returns the number of bytes written to the response body so far.
+	Size() int
+	// Written reports whether the response has been written to.
+	Written() bool
+}
+
+type responseWriter struct {
+	http.ResponseWriter
+	status int
+	size   int
+}
+
+// NewResponseWriter wraps rw, returning a ResponseWriter that tracks the
+// status code and number of bytes written.
+func NewResponseWriter(rw http.ResponseWriter) ResponseWriter {
+	return &responseWriter{ResponseWriter: rw}
+}
+
+func (rw *responseWriter) WriteHeader(s int) {
+	rw.ResponseWriter.WriteHeader(s)
+	rw.status = s
+}
+
+func (rw *responseWriter) Write(b []byte) (int, error) {
+	if !rw.Written() {
+		// WriteHeader hasn't been called explicitly; net/http would default
+		// to 200 on the first Write, so record that here too.
+		rw.WriteHeader(http.StatusOK)
+	}
+	size, err := rw.ResponseWriter.Write(b)
+	rw.size += size
+	return size, err
+}
+
+func (rw *responseWriter) Status() int {
+	return rw.status
+}
+
+func (rw *responseWriter) Size() int {
+	return rw.size
+}
+
+func (rw *responseWriter) Written() bool {
+	return rw.status != 0
+}
+
+// Hijack lets responseWriter satisfy http.Hijacker when the wrapped
+// ResponseWriter does, so middleware chains built on top of it don't break
+// websocket/h2c upgrades.
+func (rw *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := rw.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("negroni: ResponseWriter doesn't support the Hijacker interface")
+	}
+	return hijacker.Hijack()
+}
+
+// Flush lets responseWriter satisfy http.Flusher when the wrapped
+// ResponseWriter does, so streaming handlers further down the chain work.
+func (rw *responseWriter) Flush() {
+	if flusher, ok := rw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
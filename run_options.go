@@ -0,0 +1,145 @@
+package negroni
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// RunOptions configures the HTTP server started by RunWithOptions, giving
+// callers control over the timeouts, header limits, TLS and HTTP/2 support
+// that Run/RunTLS hard-code.
+type RunOptions struct {
+	ReadHeaderTimeout time.Duration
+	IdleTimeout       time.Duration
+	MaxHeaderBytes    int
+	TLSConfig         *tls.Config
+	H2C               bool
+	BaseContext       func(net.Listener) context.Context
+	OnShutdown        func()
+}
+
+// RunOption mutates a RunOptions in place, for a functional-options style
+// call to RunWithOptions.
+type RunOption func(*RunOptions)
+
+// WithReadHeaderTimeout sets RunOptions.ReadHeaderTimeout.
+func WithReadHeaderTimeout(d time.Duration) RunOption {
+	return func(o *RunOptions) { o.ReadHeaderTimeout = d }
+}
+
+// WithIdleTimeout sets RunOptions.IdleTimeout.
+func WithIdleTimeout(d time.Duration) RunOption {
+	return func(o *RunOptions) { o.IdleTimeout = d }
+}
+
+// WithMaxHeaderBytes sets RunOptions.MaxHeaderBytes.
+func WithMaxHeaderBytes(n int) RunOption {
+	return func(o *RunOptions) { o.MaxHeaderBytes = n }
+}
+
+// WithTLSConfig sets RunOptions.TLSConfig; RunWithOptions serves TLS
+// whenever it is non-nil.
+func WithTLSConfig(c *tls.Config) RunOption {
+	return func(o *RunOptions) { o.TLSConfig = c }
+}
+
+// WithH2C enables plaintext HTTP/2 (h2c) support.
+func WithH2C(enabled bool) RunOption {
+	return func(o *RunOptions) { o.H2C = enabled }
+}
+
+// WithBaseContext sets RunOptions.BaseContext.
+func WithBaseContext(f func(net.Listener) context.Context) RunOption {
+	return func(o *RunOptions) { o.BaseContext = f }
+}
+
+// WithOnShutdown registers a callback run when the server begins a graceful
+// shutdown; see http.Server.RegisterOnShutdown.
+func WithOnShutdown(f func()) RunOption {
+	return func(o *RunOptions) { o.OnShutdown = f }
+}
+
+func defaultRunOptions() RunOptions {
+	return RunOptions{
+		ReadHeaderTimeout: 10 * time.Second,
+		IdleTimeout:       120 * time.Second,
+		MaxHeaderBytes:    1 << 16,
+	}
+}
+
+// RunWithOptions runs the negroni stack as an HTTP server on addr, applying
+// opts on top of defaults of a 10s ReadHeaderTimeout, a 120s IdleTimeout and
+// a 64KiB MaxHeaderBytes. Unlike Run, it returns an error instead of exiting
+// the process on bind failure, so embedding applications
+// can decide how to react. When opts enables H2C, the handler is wrapped so
+// plaintext HTTP/2 requests are served through the middleware chain. Like
+// Run/RunTLS/RunServer/RunServerTLS, a graceful n.Shutdown makes it return
+// nil rather than http.ErrServerClosed.
+func (n *Negroni) RunWithOptions(addr string, opts ...RunOption) error {
+	options := defaultRunOptions()
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	var handler http.Handler = n
+	if options.H2C {
+		handler = h2c.NewHandler(n, &http2.Server{})
+	}
+
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: options.ReadHeaderTimeout,
+		IdleTimeout:       options.IdleTimeout,
+		MaxHeaderBytes:    options.MaxHeaderBytes,
+		TLSConfig:         options.TLSConfig,
+		BaseContext:       options.BaseContext,
+	}
+	if options.OnShutdown != nil {
+		server.RegisterOnShutdown(options.OnShutdown)
+	}
+	n.trackServer(server)
+	n.log().Infof("listening on %s", addr)
+
+	var err error
+	if options.TLSConfig != nil {
+		err = server.ListenAndServeTLS("", "")
+	} else {
+		err = server.ListenAndServe()
+	}
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// RunServerContext runs server (with its Handler set to n) until ctx is
+// done, at which point it shuts down gracefully via server.Shutdown. It
+// returns nil after a clean shutdown, or whatever error ListenAndServe
+// produced if the server failed to start.
+func (n *Negroni) RunServerContext(ctx context.Context, server *http.Server) error {
+	server.Handler = n
+	n.trackServer(server)
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	case <-ctx.Done():
+		return server.Shutdown(context.Background())
+	}
+}
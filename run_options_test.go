@@ -0,0 +1,86 @@
+package negroni
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// reserveAddr binds an ephemeral port, closes the listener, and returns its
+// address, so a *http.Server started later by addr string has a stable,
+// free port to bind to.
+func reserveAddr(t *testing.T) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("reserving a port: %v", err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+	return addr
+}
+
+func TestRunServerContextShutsDownOnCancel(t *testing.T) {
+	n := New()
+	server := &http.Server{Addr: reserveAddr(t)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- n.RunServerContext(ctx, server)
+	}()
+
+	// Give ListenAndServe a moment to bind before triggering shutdown.
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-errCh:
+		if err != nil {
+			t.Fatalf("RunServerContext: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("RunServerContext never returned after ctx was cancelled")
+	}
+}
+
+func TestRunWithOptionsH2CServesPlaintextRequests(t *testing.T) {
+	n := New()
+	n.UseFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		rw.WriteHeader(http.StatusOK)
+	})
+
+	addr := reserveAddr(t)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- n.RunWithOptions(addr, WithH2C(true))
+	}()
+
+	// Give ListenAndServe a moment to bind before dialling it.
+	time.Sleep(10 * time.Millisecond)
+
+	resp, err := http.Get(fmt.Sprintf("http://%s/", addr))
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+
+	if err := n.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+
+	select {
+	case err := <-errCh:
+		if err != nil {
+			t.Fatalf("RunWithOptions = %v, want nil after a graceful Shutdown", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("RunWithOptions never returned after Shutdown")
+	}
+}
@@ -0,0 +1,89 @@
+package negroni
+
+import (
+	"net/http"
+	"path"
+	"strings"
+)
+
+// Static is the static file serving middleware used by Classic(). It serves
+// files out of Dir and falls through to next for any request that doesn't
+// resolve to one, so it can sit ahead of the application's router without
+// swallowing requests the router needs to handle.
+type Static struct {
+	// Dir is the directory to serve static files from.
+	Dir http.FileSystem
+	// Prefix is a URL prefix to strip before looking the request path up in
+	// Dir. Left empty, the full request path is used.
+	Prefix string
+	// IndexFile is the file served for a request ending in "/". Defaults to
+	// "index.html".
+	IndexFile string
+}
+
+// NewStatic returns a Static instance serving files out of dir.
+func NewStatic(dir http.FileSystem) *Static {
+	return &Static{
+		Dir:       dir,
+		IndexFile: "index.html",
+	}
+}
+
+func (s *Static) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		next(rw, r)
+		return
+	}
+
+	file := r.URL.Path
+	if s.Prefix != "" {
+		if !strings.HasPrefix(file, s.Prefix) {
+			next(rw, r)
+			return
+		}
+		file = strings.TrimPrefix(file, s.Prefix)
+		if file != "" && file[0] != '/' {
+			next(rw, r)
+			return
+		}
+	}
+
+	f, err := s.Dir.Open(file)
+	if err != nil {
+		next(rw, r)
+		return
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		next(rw, r)
+		return
+	}
+
+	if fi.IsDir() {
+		if !strings.HasSuffix(r.URL.Path, "/") {
+			next(rw, r)
+			return
+		}
+
+		indexFile := path.Join(file, s.IndexFile)
+		index, err := s.Dir.Open(indexFile)
+		if err != nil {
+			next(rw, r)
+			return
+		}
+		defer index.Close()
+
+		indexInfo, err := index.Stat()
+		if err != nil || indexInfo.IsDir() {
+			next(rw, r)
+			return
+		}
+
+		http.ServeContent(rw, r, indexFile, indexInfo.ModTime(), index)
+		return
+	}
+
+	http.ServeContent(rw, r, file, fi.ModTime(), f)
+}